@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importx
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/vmware/govmomi/ovf/importer"
+)
+
+// isRemotePath reports whether p looks like an http(s) URL rather
+// than a path on the local filesystem.
+func isRemotePath(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// isLibraryPath reports whether p names a Content Library item, e.g.
+// "library:/MyLib/ubuntu-22.04", rather than a local file or http(s) URL.
+func isLibraryPath(p string) bool {
+	return strings.HasPrefix(p, "library:")
+}
+
+// ArchiveFlag holds the importer.Archive (FileArchive/TapeArchive/...)
+// that import.spec, import.ovf and import.ova were pointed at. It has
+// no flags of its own today - the archive type is inferred from the
+// positional PATH argument - but it gives those commands a common
+// place to hang archive-related behavior.
+type ArchiveFlag struct {
+	importer.Archive
+}
+
+func newArchiveFlag(ctx context.Context) (*ArchiveFlag, context.Context) {
+	return &ArchiveFlag{}, ctx
+}
+
+func (f *ArchiveFlag) Register(ctx context.Context, fs *flag.FlagSet) {}
+
+func (f *ArchiveFlag) Process(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importx
+
+import (
+	"context"
+	"flag"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/ovf/importer"
+)
+
+// ova is "import.ova": identical to import.ovf except the descriptor
+// and referenced files are read out of a single .ova tarball instead
+// of loose files on disk.
+type ova struct {
+	*ovfx
+}
+
+func init() {
+	cli.Register("import.ova", &ova{&ovfx{}})
+}
+
+func (cmd *ova) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ovfx.Register(ctx, f)
+}
+
+func (cmd *ova) Process(ctx context.Context) error {
+	return cmd.ovfx.Process(ctx)
+}
+
+func (cmd *ova) Usage() string {
+	return "PATH_TO_OVA"
+}
+
+func (cmd *ova) Run(ctx context.Context, f *flag.FlagSet) error {
+	fpath := f.Arg(0)
+	if fpath == "" {
+		return flag.ErrHelp
+	}
+
+	cmd.Archive = &importer.TapeArchive{Path: fpath}
+	client, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+	if isRemotePath(fpath) {
+		cmd.Archive.(*importer.TapeArchive).Client = client.Client
+	}
+
+	return cmd.ovfx.runWithArchive(ctx, cmd.Archive, "*.ovf")
+}
@@ -0,0 +1,287 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/ovf/importer"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ovfx is the "import.ovf" command. It is a thin CLI wrapper: all of
+// the descriptor parsing, spec building and upload logic lives in
+// ovf/importer, so that the same code path is reusable outside govc.
+type ovfx struct {
+	*ArchiveFlag
+	*flags.ClientFlag
+	*flags.OutputFlag
+	*flags.DatastoreFlag
+	*flags.ResourcePoolFlag
+	*flags.HostSystemFlag
+	*flags.FolderFlag
+	*flags.NetworkFlag
+
+	Options string
+	Name    string
+
+	Profile string
+	Config  string
+
+	Check bool
+
+	Progress string
+}
+
+func init() {
+	cli.Register("import.ovf", &ovfx{})
+}
+
+func (cmd *ovfx) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ArchiveFlag, ctx = newArchiveFlag(ctx)
+	cmd.ArchiveFlag.Register(ctx, f)
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+	cmd.DatastoreFlag, ctx = flags.NewDatastoreFlag(ctx)
+	cmd.DatastoreFlag.Register(ctx, f)
+	cmd.ResourcePoolFlag, ctx = flags.NewResourcePoolFlag(ctx)
+	cmd.ResourcePoolFlag.Register(ctx, f)
+	cmd.HostSystemFlag, ctx = flags.NewHostSystemFlag(ctx)
+	cmd.HostSystemFlag.Register(ctx, f)
+	cmd.FolderFlag, ctx = flags.NewFolderFlag(ctx)
+	cmd.FolderFlag.Register(ctx, f)
+	cmd.NetworkFlag, ctx = flags.NewNetworkFlag(ctx)
+	cmd.NetworkFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.Options, "options", "", "Options spec file path")
+	f.StringVar(&cmd.Name, "name", "", "Name to use for new entity")
+
+	f.StringVar(&cmd.Profile, "profile", "", "Storage profile (SPBM policy) name to apply to imported disks")
+	f.StringVar(&cmd.Config, "config", "", "Path to a ConfigSpec XML file to merge into the import spec")
+
+	f.BoolVar(&cmd.Check, "check", false, "Check that every OVF network is mapped and the target supports the OVF hardware version before importing")
+
+	f.StringVar(&cmd.Progress, "progress", "text", "Progress output format: json|text|none")
+}
+
+func (cmd *ovfx) progressSink() (importer.ProgressSink, error) {
+	switch cmd.Progress {
+	case "json":
+		return importer.NewJSONProgressSink(os.Stderr), nil
+	case "text":
+		return importer.NewTextProgressSink(os.Stderr), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid -progress value %q, want json|text|none", cmd.Progress)
+	}
+}
+
+func (cmd *ovfx) Process(ctx context.Context) error {
+	for _, p := range []interface{ Process(context.Context) error }{
+		cmd.ClientFlag, cmd.OutputFlag, cmd.DatastoreFlag, cmd.ResourcePoolFlag, cmd.HostSystemFlag, cmd.FolderFlag, cmd.NetworkFlag,
+	} {
+		if err := p.Process(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cmd *ovfx) Usage() string {
+	return "PATH_TO_OVF"
+}
+
+// checkResult renders a pre-flight importer.CheckReport. Text output
+// (the default) prints pass/fail followed by each error and warning;
+// -json emits the same report as JSON, so CI can inspect it without
+// parsing prose.
+type checkResult struct {
+	*importer.CheckReport
+}
+
+func (r *checkResult) Write(w io.Writer) error {
+	if r.Compatible {
+		fmt.Fprintln(w, "OK: import target is compatible with this OVF")
+	} else {
+		fmt.Fprintln(w, "FAIL: import target is not compatible with this OVF")
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(w, "  error: %s\n", e)
+	}
+	for _, warn := range r.Warnings {
+		fmt.Fprintf(w, "  warning: %s\n", warn)
+	}
+	return nil
+}
+
+func (cmd *ovfx) options() (*importer.Options, error) {
+	if cmd.Options == "" {
+		return &importer.Options{}, nil
+	}
+
+	var o importer.Options
+
+	f, err := os.Open(cmd.Options)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return &o, json.NewDecoder(f).Decode(&o)
+}
+
+func (cmd *ovfx) Run(ctx context.Context, f *flag.FlagSet) error {
+	fpath := f.Arg(0)
+	if fpath == "" {
+		return flag.ErrHelp
+	}
+
+	if isLibraryPath(fpath) {
+		rc, err := cmd.RestClient(ctx)
+		if err != nil {
+			return err
+		}
+		return cmd.runWithArchive(ctx, &importer.LibraryArchive{Client: rc, Path: fpath}, "*.ovf")
+	}
+
+	if path.Ext(fpath) != ".ovf" {
+		return fmt.Errorf("invalid file extension %s", path.Ext(fpath))
+	}
+	cmd.Archive = &importer.FileArchive{Path: fpath}
+
+	client, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	if isRemotePath(fpath) {
+		cmd.Archive.(*importer.FileArchive).Client = client.Client
+	}
+
+	return cmd.runWithArchive(ctx, cmd.Archive, "*.ovf")
+}
+
+// runWithArchive does the actual spec-building/import/post-import
+// work against archive. It is shared with import.ova, which only
+// differs in how it constructs the Archive and the descriptor's entry
+// name within it.
+func (cmd *ovfx) runWithArchive(ctx context.Context, archive importer.Archive, fpath string) error {
+	cmd.Archive = archive
+
+	client, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	opts, err := cmd.options()
+	if err != nil {
+		return err
+	}
+	if opts.DiskProvisioning == "" {
+		spec, err := importer.Spec(cmd.Archive, fpath, false)
+		if err != nil {
+			return err
+		}
+		opts = spec
+	}
+
+	if cmd.Profile != "" {
+		opts.StorageProfile = cmd.Profile
+	}
+
+	if cmd.Config != "" {
+		data, err := os.ReadFile(cmd.Config)
+		if err != nil {
+			return err
+		}
+		opts.ConfigSpec = base64.StdEncoding.EncodeToString(data)
+	}
+
+	pool, err := cmd.ResourcePoolFlag.ResourcePool()
+	if err != nil {
+		return err
+	}
+	host, _ := cmd.HostSystemFlag.HostSystemIfSpecified()
+	folder, err := cmd.FolderFlag.Folder()
+	if err != nil {
+		return err
+	}
+
+	networks := map[string]types.ManagedObjectReference{}
+	for _, m := range opts.NetworkMapping {
+		if m.Network == "" {
+			continue
+		}
+		net, err := cmd.NetworkFlag.NetworkByName(ctx, m.Network)
+		if err != nil {
+			return err
+		}
+		networks[m.Network] = net.Reference()
+	}
+
+	dst := importer.Destination{
+		Pool:     pool,
+		Host:     host,
+		Folder:   folder,
+		Name:     cmd.Name,
+		Networks: networks,
+	}
+
+	if cmd.Check {
+		data, err := importer.ReadOvf(fpath, cmd.Archive)
+		if err != nil {
+			return err
+		}
+		e, err := importer.ReadEnvelope(data)
+		if err != nil {
+			return err
+		}
+
+		report, err := importer.Check(ctx, client, e, dst)
+		if err != nil {
+			return err
+		}
+
+		if err := cmd.WriteResult(&checkResult{report}); err != nil {
+			return err
+		}
+
+		if !report.Compatible {
+			return fmt.Errorf("importer: import target is not compatible with this OVF, aborting before import")
+		}
+	}
+
+	progress, err := cmd.progressSink()
+	if err != nil {
+		return err
+	}
+
+	_, err = importer.Import(ctx, client, cmd.Archive, fpath, opts, dst, progress)
+	return err
+}
@@ -22,37 +22,10 @@ import (
 	"fmt"
 	"io"
 	"path"
-	"strings"
 
 	"github.com/vmware/govmomi/govc/cli"
 	"github.com/vmware/govmomi/govc/flags"
-	"github.com/vmware/govmomi/ovf"
-	"github.com/vmware/govmomi/vim25/types"
-)
-
-var (
-	allDiskProvisioningOptions = []string{
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeFlat),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeMonolithicSparse),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeMonolithicFlat),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeTwoGbMaxExtentSparse),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeTwoGbMaxExtentFlat),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThick),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeSeSparse),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick),
-		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeSparse),
-	}
-	allIPAllocationPolicyOptions = []string{
-		string(types.VAppIPAssignmentInfoIpAllocationPolicyDhcpPolicy),
-		string(types.VAppIPAssignmentInfoIpAllocationPolicyTransientPolicy),
-		string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedPolicy),
-		string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedAllocatedPolicy),
-	}
-	allIPProtocolOptions = []string{
-		string(types.VAppIPAssignmentInfoProtocolsIPv4),
-		string(types.VAppIPAssignmentInfoProtocolsIPv6),
-	}
+	"github.com/vmware/govmomi/ovf/importer"
 )
 
 type spec struct {
@@ -97,31 +70,42 @@ func (cmd *spec) Run(ctx context.Context, f *flag.FlagSet) error {
 	}
 
 	if len(fpath) > 0 {
-		switch path.Ext(fpath) {
-		case ".ovf":
-			cmd.Archive = &FileArchive{Path: fpath}
-		case "", ".ova":
-			cmd.Archive = &TapeArchive{Path: fpath}
-			fpath = "*.ovf"
-		default:
-			return fmt.Errorf("invalid file extension %s", path.Ext(fpath))
-		}
-
-		if isRemotePath(f.Arg(0)) {
-			client, err := cmd.Client()
+		switch {
+		case isLibraryPath(fpath):
+			rc, err := cmd.RestClient(ctx)
 			if err != nil {
 				return err
 			}
-			switch archive := cmd.Archive.(type) {
-			case *FileArchive:
-				archive.Client = client
-			case *TapeArchive:
-				archive.Client = client
+			cmd.Archive = &importer.LibraryArchive{Client: rc, Path: fpath}
+			fpath = "*.ovf"
+		default:
+			switch path.Ext(fpath) {
+			case ".ovf":
+				cmd.Archive = &importer.FileArchive{Path: fpath}
+				fpath = "*.ovf"
+			case "", ".ova":
+				cmd.Archive = &importer.TapeArchive{Path: fpath}
+				fpath = "*.ovf"
+			default:
+				return fmt.Errorf("invalid file extension %s", path.Ext(fpath))
+			}
+
+			if isRemotePath(f.Arg(0)) {
+				client, err := cmd.Client()
+				if err != nil {
+					return err
+				}
+				switch archive := cmd.Archive.(type) {
+				case *importer.FileArchive:
+					archive.Client = client.Client
+				case *importer.TapeArchive:
+					archive.Client = client.Client
+				}
 			}
 		}
 	}
 
-	env, err := cmd.Spec(fpath)
+	env, err := importer.Spec(cmd.Archive, fpath, cmd.Verbose())
 	if err != nil {
 		return err
 	}
@@ -133,120 +117,9 @@ func (cmd *spec) Run(ctx context.Context, f *flag.FlagSet) error {
 }
 
 type specResult struct {
-	*Options
+	*importer.Options
 }
 
 func (*specResult) Write(w io.Writer) error {
 	return nil
 }
-
-func (cmd *spec) Map(e *ovf.Envelope) (res []Property) {
-	if e == nil || e.VirtualSystem == nil {
-		return nil
-	}
-
-	for _, p := range e.VirtualSystem.Product {
-		for i, v := range p.Property {
-			if v.UserConfigurable == nil || !*v.UserConfigurable {
-				continue
-			}
-
-			d := ""
-			if v.Default != nil {
-				d = *v.Default
-			}
-
-			// vSphere only accept True/False as boolean values for some reason
-			if v.Type == "boolean" {
-				d = strings.Title(d)
-			}
-
-			// From OVF spec, section 9.5.1:
-			// key-value-env = [class-value "."] key-value-prod ["." instance-value]
-			k := v.Key
-			if p.Class != nil {
-				k = fmt.Sprintf("%s.%s", *p.Class, k)
-			}
-			if p.Instance != nil {
-				k = fmt.Sprintf("%s.%s", k, *p.Instance)
-			}
-
-			np := Property{KeyValue: KeyValue{Key: k, Value: d}}
-			if cmd.Verbose() {
-				np.Spec = &p.Property[i]
-			}
-
-			res = append(res, np)
-		}
-	}
-
-	return
-}
-
-func (cmd *spec) Spec(fpath string) (*Options, error) {
-	e := &ovf.Envelope{}
-	if fpath != "" {
-		d, err := cmd.ReadOvf(fpath)
-		if err != nil {
-			return nil, err
-		}
-
-		if e, err = cmd.ReadEnvelope(d); err != nil {
-			return nil, err
-		}
-	}
-
-	var deploymentOptions []string
-	if e.DeploymentOption != nil && e.DeploymentOption.Configuration != nil {
-		// add default first
-		for _, c := range e.DeploymentOption.Configuration {
-			if c.Default != nil && *c.Default {
-				deploymentOptions = append(deploymentOptions, c.ID)
-			}
-		}
-
-		for _, c := range e.DeploymentOption.Configuration {
-			if c.Default == nil || !*c.Default {
-				deploymentOptions = append(deploymentOptions, c.ID)
-			}
-		}
-	}
-
-	o := Options{
-		DiskProvisioning:   allDiskProvisioningOptions[0],
-		IPAllocationPolicy: allIPAllocationPolicyOptions[0],
-		IPProtocol:         allIPProtocolOptions[0],
-		MarkAsTemplate:     false,
-		PowerOn:            false,
-		WaitForIP:          false,
-		InjectOvfEnv:       false,
-		PropertyMapping:    cmd.Map(e),
-	}
-
-	if deploymentOptions != nil {
-		o.Deployment = deploymentOptions[0]
-	}
-
-	if e.VirtualSystem != nil && e.VirtualSystem.Annotation != nil {
-		for _, a := range e.VirtualSystem.Annotation {
-			o.Annotation += a.Annotation
-		}
-	}
-
-	if e.Network != nil {
-		for _, net := range e.Network.Networks {
-			o.NetworkMapping = append(o.NetworkMapping, Network{net.Name, ""})
-		}
-	}
-
-	if cmd.Verbose() {
-		if deploymentOptions != nil {
-			o.AllDeploymentOptions = deploymentOptions
-		}
-		o.AllDiskProvisioningOptions = allDiskProvisioningOptions
-		o.AllIPAllocationPolicyOptions = allIPAllocationPolicyOptions
-		o.AllIPProtocolOptions = allIPProtocolOptions
-	}
-
-	return &o, nil
-}
@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// isRemotePath reports whether p is an http(s) URL rather than a path
+// on the local filesystem.
+func isRemotePath(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// Archive abstracts the various sources an OVF/OVA descriptor and the
+// files it references (VMDKs, ISOs, ...) can be read from: a
+// directory of loose files (FileArchive), a single .ova tarball
+// (TapeArchive), or a vCenter Content Library item (LibraryArchive).
+type Archive interface {
+	// Open returns a reader for name, which is either an exact entry
+	// name or, for descriptors, the glob pattern "*.ovf".
+	Open(name string) (io.ReadCloser, error)
+}
+
+// FileArchive resolves files relative to Path on the local
+// filesystem, or over http(s) when Path is a URL and Client is set.
+type FileArchive struct {
+	Path   string
+	Client *soap.Client
+}
+
+func (t *FileArchive) Open(name string) (io.ReadCloser, error) {
+	fpath := name
+	if fpath == "" || fpath == "*.ovf" {
+		fpath = t.Path
+	} else {
+		fpath = path.Join(path.Dir(t.Path), name)
+	}
+
+	if isRemotePath(fpath) {
+		if t.Client == nil {
+			return nil, errors.New("importer: remote FileArchive requires a Client")
+		}
+
+		req, err := http.NewRequest(http.MethodGet, fpath, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := t.Client.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return nil, errors.New("importer: " + res.Status)
+		}
+
+		return res.Body, nil
+	}
+
+	return os.Open(fpath)
+}
+
+// TapeArchive reads files out of a .ova tarball, either on the local
+// filesystem or, when Client is set, streamed over http(s).
+type TapeArchive struct {
+	Path   string
+	Client *soap.Client
+}
+
+func (t *TapeArchive) open() (io.ReadCloser, error) {
+	if isRemotePath(t.Path) {
+		if t.Client == nil {
+			return nil, errors.New("importer: remote TapeArchive requires a Client")
+		}
+
+		req, err := http.NewRequest(http.MethodGet, t.Path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := t.Client.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return nil, errors.New("importer: " + res.Status)
+		}
+
+		return res.Body, nil
+	}
+
+	return os.Open(t.Path)
+}
+
+func (t *TapeArchive) Open(name string) (io.ReadCloser, error) {
+	f, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	r := tar.NewReader(f)
+
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			f.Close()
+			return nil, os.ErrNotExist
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		matched := h.Name == name
+		if !matched && name == "*.ovf" {
+			matched = strings.EqualFold(filepath.Ext(h.Name), ".ovf")
+		}
+
+		if matched {
+			return &tarEntry{Reader: r, underlying: f}, nil
+		}
+	}
+}
+
+// tarEntry adapts a single tar.Reader entry to io.ReadCloser, closing
+// the underlying archive stream once the caller is done with it.
+type tarEntry struct {
+	io.Reader
+	underlying io.Closer
+}
+
+func (t *tarEntry) Close() error {
+	return t.underlying.Close()
+}
+
+// ReadOvf reads the OVF descriptor named fpath (or the archive's sole
+// ".ovf" entry when fpath is "*.ovf") out of archive.
+func ReadOvf(fpath string, archive Archive) ([]byte, error) {
+	f, err := archive.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// ReadEnvelope unmarshals an OVF descriptor.
+func ReadEnvelope(data []byte) (*ovf.Envelope, error) {
+	return ovf.Unmarshal(bytes.NewReader(data))
+}
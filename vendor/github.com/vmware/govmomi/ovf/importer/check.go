@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25"
+)
+
+// CheckReport is the result of a pre-flight compatibility pass
+// against the hardware described by an OVF envelope: whether the
+// chosen pool's compute resource can run it, before CreateImportSpec/
+// ImportVApp touch anything or a single byte is uploaded.
+//
+// Note this is deliberately scoped to what can be checked without an
+// existing VM: CheckCompatibility_Task/CheckProvisioning_Task operate
+// on an already-created VM moref, so they can't preflight a spec that
+// doesn't exist yet. Hardware version support and required network
+// presence are checked instead.
+type CheckReport struct {
+	Compatible bool
+	Warnings   []string `json:",omitempty"`
+	Errors     []string `json:",omitempty"`
+}
+
+// hardwareVersion returns the OVF-declared virtual hardware version
+// (e.g. "vmx-19"), or "" if the envelope doesn't specify one.
+func hardwareVersion(e *ovf.Envelope) string {
+	if e.VirtualSystem == nil || len(e.VirtualSystem.VirtualHardware) == 0 || e.VirtualSystem.VirtualHardware[0].System == nil {
+		return ""
+	}
+	return e.VirtualSystem.VirtualHardware[0].System.VirtualSystemType
+}
+
+// Check validates the hardware described by e against dst: that every
+// network the OVF requires has a mapping in dst.Networks, and that
+// dst's compute resource supports the OVF's declared hardware
+// version.
+func Check(ctx context.Context, client *vim25.Client, e *ovf.Envelope, dst Destination) (*CheckReport, error) {
+	report := &CheckReport{Compatible: true}
+
+	if e.Network != nil {
+		for _, net := range e.Network.Networks {
+			if _, ok := dst.Networks[net.Name]; !ok {
+				report.Compatible = false
+				report.Errors = append(report.Errors, fmt.Sprintf("network %q has no mapping to an inventory network", net.Name))
+			}
+		}
+	}
+
+	if version := hardwareVersion(e); version != "" && dst.Pool != nil {
+		cr, err := dst.Pool.Owner(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("importer: resolving compute resource: %s", err)
+		}
+
+		eb, err := cr.EnvironmentBrowser(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("importer: environment browser: %s", err)
+		}
+
+		descriptors, err := eb.QueryConfigOptionDescriptor(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("importer: querying supported hardware versions: %s", err)
+		}
+
+		supported := false
+		for _, d := range descriptors {
+			if d.Key == version {
+				supported = true
+				break
+			}
+		}
+
+		if !supported {
+			report.Compatible = false
+			report.Errors = append(report.Errors, fmt.Sprintf("hardware version %q is not supported by the target compute resource", version))
+		}
+	}
+
+	return report, nil
+}
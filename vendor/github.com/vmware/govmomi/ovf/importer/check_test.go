@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestHardwareVersionEmptyEnvelope(t *testing.T) {
+	if v := hardwareVersion(&ovf.Envelope{}); v != "" {
+		t.Errorf("got %q, want empty string for an envelope with no VirtualSystem", v)
+	}
+}
+
+func TestHardwareVersion(t *testing.T) {
+	e := &ovf.Envelope{
+		VirtualSystem: &ovf.VirtualSystem{
+			VirtualHardware: []ovf.VirtualHardwareSection{
+				{System: &ovf.VirtualSystemSettingData{VirtualSystemType: "vmx-19"}},
+			},
+		},
+	}
+
+	if v := hardwareVersion(e); v != "vmx-19" {
+		t.Errorf("got %q, want vmx-19", v)
+	}
+}
+
+func TestCheckMissingNetworkMapping(t *testing.T) {
+	e := &ovf.Envelope{
+		Network: &ovf.NetworkSection{
+			Networks: []ovf.Network{{Name: "VM Network"}},
+		},
+	}
+
+	report, err := Check(context.Background(), nil, e, Destination{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Compatible {
+		t.Error("expected Compatible=false when a required network has no mapping")
+	}
+	if len(report.Errors) != 1 {
+		t.Errorf("got %d errors, want 1", len(report.Errors))
+	}
+}
+
+func TestCheckSatisfiedNetworkMapping(t *testing.T) {
+	e := &ovf.Envelope{
+		Network: &ovf.NetworkSection{
+			Networks: []ovf.Network{{Name: "VM Network"}},
+		},
+	}
+
+	dst := Destination{Networks: map[string]types.ManagedObjectReference{
+		"VM Network": {Type: "Network", Value: "network-1"},
+	}}
+
+	report, err := Check(context.Background(), nil, e, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Compatible {
+		t.Errorf("expected Compatible=true, got errors: %v", report.Errors)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/vmware/govmomi/nfc"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Destination names the inventory objects an OVF/OVA is imported
+// into, plus the OVF-network-name -> inventory-network moref
+// resolution. Unlike Options, none of this is serialized to JSON - it
+// comes from govc's -host/-pool/-ds/-folder/-net flags (or the
+// caller's equivalent) at Import time, since morefs aren't portable
+// across vCenters the way an Options spec is.
+type Destination struct {
+	Pool     *object.ResourcePool
+	Host     *object.HostSystem
+	Folder   *object.Folder
+	Name     string
+	Networks map[string]types.ManagedObjectReference
+}
+
+// Import parses the OVF descriptor read from archive at fpath, builds
+// an ImportSpec from opts, creates the target VM/vApp in dst, and
+// uploads every OvfFileItem the spec asks for. progress, if non-nil,
+// receives structured Events for each file uploaded, lease state
+// transitions, and any post-import tasks opts asks for (mark-as-
+// template, power-on, wait-for-IP).
+func Import(ctx context.Context, client *vim25.Client, archive Archive, fpath string, opts *Options, dst Destination, progress ProgressSink) (*object.VirtualMachine, error) {
+	d, err := ReadOvf(fpath, archive)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := ReadEnvelope(d)
+	if err != nil {
+		return nil, fmt.Errorf("importer: failed to parse ovf: %s", err)
+	}
+
+	name := "Virtual Appliance"
+	if e.VirtualSystem != nil && e.VirtualSystem.Name != nil {
+		name = *e.VirtualSystem.Name
+	}
+	if dst.Name != "" {
+		name = dst.Name
+	}
+
+	ipPolicy, ipProtocol := networkIPPolicy(opts)
+
+	cisp := types.OvfCreateImportSpecParams{
+		EntityName:         name,
+		DiskProvisioning:   opts.DiskProvisioning,
+		IpAllocationPolicy: ipPolicy,
+		IpProtocol:         ipProtocol,
+		PropertyMapping:    convertProperties(opts.PropertyMapping),
+		NetworkMapping:     convertNetworks(opts.NetworkMapping, dst.Networks),
+		DeploymentOption:   opts.Deployment,
+	}
+
+	m := object.NewOvfManager(client)
+	spec, err := m.CreateImportSpec(ctx, string(d), dst.Pool, dst.Host, cisp)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Error != nil {
+		return nil, fmt.Errorf("importer: %s", spec.Error[0].LocalizedMessage)
+	}
+
+	if vmSpec, ok := spec.ImportSpec.(*types.VirtualMachineImportSpec); ok {
+		if err := applyDiskProvisioning(vmSpec, diskIDs(e), opts.DiskMapping); err != nil {
+			return nil, err
+		}
+
+		if opts.Annotation != "" {
+			vmSpec.ConfigSpec.Annotation = opts.Annotation
+		}
+
+		if opts.InjectOvfEnv {
+			if err := injectOvfEnv(vmSpec, opts.PropertyMapping); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := applyOverlay(ctx, client, spec, opts); err != nil {
+		return nil, err
+	}
+
+	report(progress, Event{Phase: "lease", Message: "requesting NFC lease"})
+	lease, err := dst.Pool.ImportVApp(ctx, spec.ImportSpec, dst.Folder, dst.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := lease.Wait(ctx, spec.FileItem)
+	if err != nil {
+		return nil, err
+	}
+	report(progress, Event{Phase: "lease", Message: "lease ready"})
+
+	if err := uploadFileItems(ctx, archive, lease, info, progress); err != nil {
+		lease.Abort(ctx, nil)
+		return nil, err
+	}
+
+	if err := lease.Complete(ctx); err != nil {
+		return nil, err
+	}
+	report(progress, Event{Phase: "lease", Message: "lease complete"})
+
+	vm := object.NewVirtualMachine(client, info.Entity)
+
+	if opts.MarkAsTemplate {
+		report(progress, Event{Phase: "mark-as-template"})
+		if err := vm.MarkAsTemplate(ctx); err != nil {
+			return nil, err
+		}
+		return vm, nil
+	}
+
+	if opts.PowerOn {
+		report(progress, Event{Phase: "power-on"})
+		task, err := vm.PowerOn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := task.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.WaitForIP {
+		report(progress, Event{Phase: "wait-for-ip"})
+		if _, err := vm.WaitForIP(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return vm, nil
+}
+
+// uploadFileItems uploads each of the lease's file items, reading its
+// source bytes from archive. When progress is non-nil, each file's
+// upload is wrapped to emit an Event per chunk read, so long uploads -
+// especially TapeArchive streams - are observable per file rather
+// than as a single opaque blob.
+func uploadFileItems(ctx context.Context, archive Archive, lease *nfc.Lease, info *nfc.LeaseInfo, progress ProgressSink) error {
+	for _, item := range info.Items {
+		f, err := archive.Open(item.Path)
+		if err != nil {
+			return err
+		}
+
+		var r io.Reader = f
+		if progress != nil {
+			r = &progressReader{Reader: f, sink: progress, file: item.Path, total: item.Size}
+		}
+
+		err = lease.Upload(ctx, item, r, soap.Upload{
+			Type:          "application/x-vnd.vmware-streamVmdk",
+			Method:        "POST",
+			ContentLength: item.Size,
+		})
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// progressReader wraps an upload source, reporting an "upload" Event
+// with cumulative bytes/percent on every Read.
+type progressReader struct {
+	io.Reader
+	sink  ProgressSink
+	file  string
+	total int64
+	read  int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+
+	pct := float64(0)
+	if r.total > 0 {
+		pct = float64(r.read) / float64(r.total) * 100
+	}
+	r.sink.Report(Event{Phase: "upload", File: r.file, Bytes: r.read, Total: r.total, Percent: pct})
+
+	return n, err
+}
+
+func convertNetworks(in []Network, resolved map[string]types.ManagedObjectReference) (out []types.OvfNetworkMapping) {
+	for _, n := range in {
+		if ref, ok := resolved[n.Network]; ok {
+			out = append(out, types.OvfNetworkMapping{Name: n.Name, Network: ref})
+		}
+	}
+	return
+}
+
+func convertProperties(in []Property) (out []types.KeyValue) {
+	for _, p := range in {
+		out = append(out, types.KeyValue{Key: p.Key, Value: p.Value})
+	}
+	return
+}
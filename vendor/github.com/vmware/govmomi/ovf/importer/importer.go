@@ -0,0 +1,281 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importer implements the OVF/OVA import workflow shared by
+// govc's import.spec, import.ovf and import.ova commands: parsing an
+// OVF envelope, deriving a default Options spec from it, and driving
+// CreateImportSpec/ImportVApp against a vim25 client to deploy it.
+//
+// It exists as a standalone package (rather than living in
+// govc/importx) so that callers other than govc's CLI - provisioners,
+// packer-style builders, CI tooling - can reuse the same code path
+// without shelling out to govc.
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	allDiskProvisioningOptions = []string{
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeFlat),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeMonolithicSparse),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeMonolithicFlat),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeTwoGbMaxExtentSparse),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeTwoGbMaxExtentFlat),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThick),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeSeSparse),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick),
+		string(types.OvfCreateImportSpecParamsDiskProvisioningTypeSparse),
+	}
+	allIPAllocationPolicyOptions = []string{
+		string(types.VAppIPAssignmentInfoIpAllocationPolicyDhcpPolicy),
+		string(types.VAppIPAssignmentInfoIpAllocationPolicyTransientPolicy),
+		string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedPolicy),
+		string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedAllocatedPolicy),
+	}
+	allIPProtocolOptions = []string{
+		string(types.VAppIPAssignmentInfoProtocolsIPv4),
+		string(types.VAppIPAssignmentInfoProtocolsIPv6),
+	}
+)
+
+// KeyValue is a single OVF property assignment.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// Property is a user-configurable OVF property, optionally annotated
+// with its full ovf.Property definition when verbose output is requested.
+type Property struct {
+	KeyValue
+	Spec *ovf.Property `json:",omitempty"`
+}
+
+// Network maps an OVF network name to the name of a network that
+// already exists in the vSphere inventory.
+//
+// There is deliberately no per-network IPAllocationPolicy/IPProtocol
+// here: CreateImportSpec accepts only one of each for the whole
+// import (OvfNetworkMapping has no per-network IP-policy field), so a
+// per-network override is not a capability this package can actually
+// deliver. Options.IPAllocationPolicy/IPProtocol apply to every
+// network.
+type Network struct {
+	Name    string
+	Network string
+}
+
+// Disk overrides disk provisioning for a single OVF disk, matched by
+// its OVF diskId (fileRef is not consulted). Left empty, DiskProvisioning falls back to
+// the Options-level default - so an old, purely-scalar Options JSON
+// document still applies one provisioning type to every disk.
+type Disk struct {
+	ID string
+
+	DiskProvisioning string `json:",omitempty"`
+}
+
+// Options describes how an OVF/OVA should be imported. It is produced
+// by Spec, consumed by Import, and is also the type marshaled to/from
+// JSON by govc's import.spec and the -options flag of import.ovf and
+// import.ova, so that a spec can be generated once, edited by hand,
+// and replayed later.
+type Options struct {
+	Name *string `json:",omitempty"`
+
+	Annotation string `json:",omitempty"`
+
+	DiskProvisioning string
+
+	IPAllocationPolicy string
+
+	IPProtocol string
+
+	PropertyMapping []Property
+
+	NetworkMapping []Network
+
+	// DiskMapping holds one entry per OVF disk, for per-disk
+	// provisioning overrides (e.g. eagerZeroedThick for a DB volume,
+	// thin for everything else). It is populated by Spec from the
+	// envelope's DiskSection; entries with an empty DiskProvisioning
+	// use the Options-level default.
+	DiskMapping []Disk `json:",omitempty"`
+
+	MarkAsTemplate bool
+
+	PowerOn bool
+
+	InjectOvfEnv bool
+
+	WaitForIP bool
+
+	Deployment string
+
+	// StorageProfile is the name of an SPBM storage policy (e.g. a
+	// vSAN or encryption policy) to apply to the imported VM's disks.
+	// It is resolved to a types.VirtualMachineDefinedProfileSpec via
+	// the pbm client at Import time.
+	StorageProfile string `json:",omitempty"`
+
+	// ConfigSpec is a base64-encoded, XML-serialized
+	// types.VirtualMachineConfigSpec that is merged into the
+	// ImportSpec's VM config after CreateImportSpec returns, so that
+	// hardware not described by the OVF itself - extra NICs, pvSCSI
+	// controllers, a vTPM - can be added without a second reconfigure
+	// after import.
+	ConfigSpec string `json:",omitempty"`
+
+	AllDeploymentOptions []string `json:",omitempty"`
+
+	AllDiskProvisioningOptions []string `json:",omitempty"`
+
+	AllIPAllocationPolicyOptions []string `json:",omitempty"`
+
+	AllIPProtocolOptions []string `json:",omitempty"`
+}
+
+// Spec parses the OVF envelope read from archive at fpath (the entry
+// name within the archive, e.g. "*.ovf" for a TapeArchive, or "" when
+// there is no descriptor to read) and returns the default Options for
+// importing it. verbose controls whether the "All*Options" enumeration
+// fields and the full ovf.Property specs are populated.
+func Spec(archive Archive, fpath string, verbose bool) (*Options, error) {
+	e := &ovf.Envelope{}
+	if fpath != "" {
+		d, err := ReadOvf(fpath, archive)
+		if err != nil {
+			return nil, err
+		}
+
+		if e, err = ReadEnvelope(d); err != nil {
+			return nil, err
+		}
+	}
+
+	var deploymentOptions []string
+	if e.DeploymentOption != nil && e.DeploymentOption.Configuration != nil {
+		// add default first
+		for _, c := range e.DeploymentOption.Configuration {
+			if c.Default != nil && *c.Default {
+				deploymentOptions = append(deploymentOptions, c.ID)
+			}
+		}
+
+		for _, c := range e.DeploymentOption.Configuration {
+			if c.Default == nil || !*c.Default {
+				deploymentOptions = append(deploymentOptions, c.ID)
+			}
+		}
+	}
+
+	o := Options{
+		DiskProvisioning:   allDiskProvisioningOptions[0],
+		IPAllocationPolicy: allIPAllocationPolicyOptions[0],
+		IPProtocol:         allIPProtocolOptions[0],
+		MarkAsTemplate:     false,
+		PowerOn:            false,
+		WaitForIP:          false,
+		InjectOvfEnv:       false,
+		PropertyMapping:    Map(e, verbose),
+	}
+
+	if deploymentOptions != nil {
+		o.Deployment = deploymentOptions[0]
+	}
+
+	if e.VirtualSystem != nil && e.VirtualSystem.Annotation != nil {
+		for _, a := range e.VirtualSystem.Annotation {
+			o.Annotation += a.Annotation
+		}
+	}
+
+	if e.Network != nil {
+		for _, net := range e.Network.Networks {
+			o.NetworkMapping = append(o.NetworkMapping, Network{Name: net.Name})
+		}
+	}
+
+	if e.Disk != nil {
+		for _, disk := range e.Disk.Disks {
+			o.DiskMapping = append(o.DiskMapping, Disk{ID: disk.DiskID})
+		}
+	}
+
+	if verbose {
+		if deploymentOptions != nil {
+			o.AllDeploymentOptions = deploymentOptions
+		}
+		o.AllDiskProvisioningOptions = allDiskProvisioningOptions
+		o.AllIPAllocationPolicyOptions = allIPAllocationPolicyOptions
+		o.AllIPProtocolOptions = allIPProtocolOptions
+	}
+
+	return &o, nil
+}
+
+// Map returns the set of user-configurable properties declared by e,
+// defaulted from the envelope, with their full ovf.Property attached
+// when verbose is set.
+func Map(e *ovf.Envelope, verbose bool) (res []Property) {
+	if e == nil || e.VirtualSystem == nil {
+		return nil
+	}
+
+	for _, p := range e.VirtualSystem.Product {
+		for i, v := range p.Property {
+			if v.UserConfigurable == nil || !*v.UserConfigurable {
+				continue
+			}
+
+			d := ""
+			if v.Default != nil {
+				d = *v.Default
+			}
+
+			// vSphere only accept True/False as boolean values for some reason
+			if v.Type == "boolean" {
+				d = strings.Title(d)
+			}
+
+			// From OVF spec, section 9.5.1:
+			// key-value-env = [class-value "."] key-value-prod ["." instance-value]
+			k := v.Key
+			if p.Class != nil {
+				k = fmt.Sprintf("%s.%s", *p.Class, k)
+			}
+			if p.Instance != nil {
+				k = fmt.Sprintf("%s.%s", k, *p.Instance)
+			}
+
+			np := Property{KeyValue: KeyValue{Key: k, Value: d}}
+			if verbose {
+				np.Spec = &p.Property[i]
+			}
+
+			res = append(res, np)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,248 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vapi/library"
+	"github.com/vmware/govmomi/vapi/rest"
+)
+
+// libraryPrefix marks a PATH argument as a Content Library item
+// rather than a local file or http(s) URL, e.g.
+// "library:/MyLib/ubuntu-22.04".
+const libraryPrefix = "library:"
+
+// isLibraryPath reports whether p names a Content Library item,
+// analogous to isRemotePath for http(s) URLs.
+func isLibraryPath(p string) bool {
+	return strings.HasPrefix(p, libraryPrefix)
+}
+
+// LibraryArchive reads an OVF descriptor and its referenced files
+// directly out of a vCenter Content Library item, so that
+// import.spec/import.ovf can target a library item the same way they
+// target a local .ovf/.ova: by streaming each file from the library's
+// download session straight into the HTTP NFC lease, without a
+// round-trip through local disk.
+type LibraryArchive struct {
+	Client *rest.Client
+
+	// Path is "library:/<library name>/<item name>".
+	Path string
+
+	item  *library.Item
+	files []library.File
+}
+
+func (a *LibraryArchive) resolve(ctx context.Context) (*library.Item, error) {
+	if a.item != nil {
+		return a.item, nil
+	}
+
+	p := strings.Trim(strings.TrimPrefix(a.Path, libraryPrefix), "/")
+	libName, itemName := path.Split(p)
+	libName = strings.Trim(libName, "/")
+	if libName == "" || itemName == "" {
+		return nil, fmt.Errorf("importer: invalid library path %q, want library:/<library>/<item>", a.Path)
+	}
+
+	m := library.NewManager(a.Client)
+
+	libs, err := m.GetLibraries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("importer: listing libraries: %s", err)
+	}
+
+	var libID string
+	for _, l := range libs {
+		if l.Name == libName {
+			libID = l.ID
+			break
+		}
+	}
+	if libID == "" {
+		return nil, fmt.Errorf("importer: library %q not found", libName)
+	}
+
+	items, err := m.FindLibraryItems(ctx, library.FindItem{Name: itemName, LibraryID: libID})
+	if err != nil {
+		return nil, fmt.Errorf("importer: finding item %q: %s", itemName, err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("importer: item %q not found in library %q", itemName, libName)
+	}
+
+	item, err := m.GetLibraryItem(ctx, items[0])
+	if err != nil {
+		return nil, err
+	}
+
+	a.item = item
+	return item, nil
+}
+
+// listFiles returns the library item's files, listing them once and
+// caching the result - every Open call (descriptor, then each disk)
+// otherwise re-lists the same, unchanging set.
+func (a *LibraryArchive) listFiles(ctx context.Context, item *library.Item) ([]library.File, error) {
+	if a.files != nil {
+		return a.files, nil
+	}
+
+	m := library.NewManager(a.Client)
+
+	files, err := m.ListLibraryItemFiles(ctx, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("importer: listing files for %q: %s", item.Name, err)
+	}
+
+	a.files = files
+	return files, nil
+}
+
+// preparedFileDownloadTimeout bounds how long Open waits for a
+// requested file to leave the PREPARING state before giving up.
+const preparedFileDownloadTimeout = 5 * time.Minute
+
+// waitForPrepared polls the download session's state for file until
+// it reports PREPARED, so Open's GET of DownloadEndpoint.URI doesn't
+// race a file that the Content Library service is still staging.
+func waitForPrepared(ctx context.Context, m *library.Manager, session, file string) (*library.DownloadFile, error) {
+	deadline := time.Now().Add(preparedFileDownloadTimeout)
+
+	for {
+		info, err := m.GetLibraryItemDownloadSessionFile(ctx, session, file)
+		if err != nil {
+			return nil, fmt.Errorf("importer: checking download status of %q: %s", file, err)
+		}
+
+		switch info.Status {
+		case "PREPARED":
+			return info, nil
+		case "ERROR":
+			return nil, fmt.Errorf("importer: preparing %q for download failed: %+v", file, info.ErrorMessage)
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("importer: timed out waiting for %q to become ready for download", file)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// sessionCloser wraps a download's response body so that closing it -
+// the caller is done reading the file - also deletes the download
+// session, rather than leaving it to expire on its own.
+type sessionCloser struct {
+	io.ReadCloser
+	ctx     context.Context
+	m       *library.Manager
+	session string
+}
+
+func (c *sessionCloser) Close() error {
+	err := c.ReadCloser.Close()
+	if derr := c.m.DeleteLibraryItemDownloadSession(c.ctx, c.session); derr != nil && err == nil {
+		err = fmt.Errorf("importer: deleting download session: %s", derr)
+	}
+	return err
+}
+
+// Open resolves name (an exact library file name, or the glob
+// "*.ovf" for the descriptor) against the library item's files and
+// streams it back via a library download session. The session is
+// created fresh per file (the service ties a session to one file's
+// prepare/download lifecycle) and deleted once the caller closes the
+// returned reader.
+func (a *LibraryArchive) Open(name string) (io.ReadCloser, error) {
+	ctx := context.Background()
+
+	item, err := a.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.listFiles(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	fname := ""
+	for _, f := range files {
+		matched := f.Name == name
+		if !matched && name == "*.ovf" {
+			matched = strings.EqualFold(path.Ext(f.Name), ".ovf")
+		}
+		if matched {
+			fname = f.Name
+			break
+		}
+	}
+	if fname == "" {
+		return nil, fmt.Errorf("importer: %q not found in library item %q", name, item.Name)
+	}
+
+	m := library.NewManager(a.Client)
+
+	session, err := m.CreateLibraryItemDownloadSession(ctx, library.Session{LibraryItemID: item.ID})
+	if err != nil {
+		return nil, fmt.Errorf("importer: creating download session: %s", err)
+	}
+
+	if _, err := m.PrepareLibraryItemFile(ctx, session, fname); err != nil {
+		m.DeleteLibraryItemDownloadSession(ctx, session)
+		return nil, fmt.Errorf("importer: preparing %q for download: %s", fname, err)
+	}
+
+	info, err := waitForPrepared(ctx, m, session, fname)
+	if err != nil {
+		m.DeleteLibraryItemDownloadSession(ctx, session)
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, info.DownloadEndpoint.URI, nil)
+	if err != nil {
+		m.DeleteLibraryItemDownloadSession(ctx, session)
+		return nil, err
+	}
+
+	res, err := a.Client.Client.Do(req)
+	if err != nil {
+		m.DeleteLibraryItemDownloadSession(ctx, session)
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		m.DeleteLibraryItemDownloadSession(ctx, session)
+		return nil, fmt.Errorf("importer: downloading %q: %s", fname, res.Status)
+	}
+
+	return &sessionCloser{ReadCloser: res.Body, ctx: ctx, m: m, session: session}, nil
+}
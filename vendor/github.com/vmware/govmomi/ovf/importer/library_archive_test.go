@@ -0,0 +1,36 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import "testing"
+
+func TestIsLibraryPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"library:/MyLib/ubuntu-22.04", true},
+		{"/local/path.ovf", false},
+		{"https://example.com/a.ovf", false},
+	}
+
+	for _, test := range tests {
+		if got := isLibraryPath(test.path); got != test.want {
+			t.Errorf("isLibraryPath(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"fmt"
+
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// diskIDs returns e's OVF disk ids in DiskSection order - the same
+// order Spec walks to build Options.DiskMapping, and the order
+// CreateImportSpec walks the same envelope to build its VirtualDisk
+// device changes - so it supplies the id applyDiskProvisioning needs
+// to look up each positional device change in mapping.
+func diskIDs(e *ovf.Envelope) (ids []string) {
+	if e.Disk == nil {
+		return nil
+	}
+	for _, d := range e.Disk.Disks {
+		ids = append(ids, d.DiskID)
+	}
+	return
+}
+
+// networkIPPolicy returns the IPAllocationPolicy/IPProtocol to pass to
+// CreateImportSpec. This is necessarily an Options-level default, not
+// a per-network choice: OvfNetworkMapping (what CreateImportSpec
+// actually accepts for network mapping) has no per-network IP-policy
+// field, so a genuinely different policy per network (e.g. fixedPolicy
+// on the management network, dhcpPolicy on the workload network) is
+// not something this API can express. Network does not carry
+// IPAllocationPolicy/IPProtocol fields for that reason - advertising
+// them on a per-network Options schema entry would promise a
+// capability CreateImportSpec can't deliver.
+func networkIPPolicy(opts *Options) (policy, protocol string) {
+	return opts.IPAllocationPolicy, opts.IPProtocol
+}
+
+// diskProvisioningBacking maps an OvfCreateImportSpecParamsDiskProvisioningType
+// to the VirtualDiskFlatVer2BackingInfo flags it corresponds to on a
+// datastore-backed disk. Only the four types that actually describe a
+// datastore-backed disk's provisioning are supported; the rest
+// (monolithicSparse, monolithicFlat, twoGbMaxExtentSparse,
+// twoGbMaxExtentFlat, seSparse, sparse) are local/hosted vmdk formats
+// that ImportVApp never produces on a datastore, so a per-disk
+// override to one of them is an error rather than a silent no-op.
+func diskProvisioningBacking(provisioning string) (thin, eager bool, err error) {
+	switch types.OvfCreateImportSpecParamsDiskProvisioningType(provisioning) {
+	case types.OvfCreateImportSpecParamsDiskProvisioningTypeThin:
+		return true, false, nil
+	case types.OvfCreateImportSpecParamsDiskProvisioningTypeThick,
+		types.OvfCreateImportSpecParamsDiskProvisioningTypeFlat:
+		return false, false, nil
+	case types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick:
+		return false, true, nil
+	default:
+		return false, false, fmt.Errorf("importer: disk provisioning %q is not supported for a per-disk override (only thin, thick, flat and eagerZeroedThick apply to a datastore-backed disk)", provisioning)
+	}
+}
+
+// diskDeviceChanges returns, in order, the VirtualDeviceConfigSpec of
+// every VirtualDisk device CreateImportSpec added to vmSpec.
+func diskDeviceChanges(vmSpec *types.VirtualMachineImportSpec) (disks []*types.VirtualDeviceConfigSpec) {
+	for _, change := range vmSpec.ConfigSpec.DeviceChange {
+		spec := change.GetVirtualDeviceConfigSpec()
+		if spec == nil {
+			continue
+		}
+		if _, ok := spec.Device.(*types.VirtualDisk); ok {
+			disks = append(disks, spec)
+		}
+	}
+	return
+}
+
+// applyDiskProvisioning overrides the provisioning of individual
+// virtual disks in vmSpec's ConfigSpec.DeviceChange after
+// CreateImportSpec has applied its single, uniform DiskProvisioning
+// value to every disk.
+//
+// mapping is keyed by OVF diskId (Disk.ID), not by position: vmSpec's
+// VirtualDisk device changes carry no OVF diskId of their own (nor
+// does the datastore path CreateImportSpec assigns each disk's
+// backing file), so diskIDs - the same envelope's DiskSection walked
+// in CreateImportSpec's device order - supplies the id for each
+// position. diskIDs and vmSpec's disks both come from one
+// CreateImportSpec call against one envelope within the same Import,
+// so pairing them by position is sound; mapping, by contrast, may be
+// a hand-edited or independently-ordered Options document, so it is
+// looked up by id rather than trusted to share that order.
+func applyDiskProvisioning(vmSpec *types.VirtualMachineImportSpec, ids []string, mapping []Disk) error {
+	disks := diskDeviceChanges(vmSpec)
+
+	if len(ids) != len(disks) {
+		return fmt.Errorf("importer: envelope declares %d disks but CreateImportSpec returned %d disk device changes, cannot safely apply per-disk provisioning overrides", len(ids), len(disks))
+	}
+
+	byID := make(map[string]Disk, len(mapping))
+	for _, m := range mapping {
+		byID[m.ID] = m
+	}
+
+	for i, id := range ids {
+		m, ok := byID[id]
+		if !ok || m.DiskProvisioning == "" {
+			continue
+		}
+
+		backing, ok := disks[i].Device.(*types.VirtualDisk).Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		if !ok {
+			continue
+		}
+
+		thin, eager, err := diskProvisioningBacking(m.DiskProvisioning)
+		if err != nil {
+			return err
+		}
+
+		backing.ThinProvisioned = types.NewBool(thin)
+		backing.EagerlyScrub = types.NewBool(eager)
+	}
+
+	return nil
+}
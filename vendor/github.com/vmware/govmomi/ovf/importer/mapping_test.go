@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestNetworkIPPolicy(t *testing.T) {
+	opts := &Options{IPAllocationPolicy: "fixedPolicy", IPProtocol: "IPv4"}
+
+	policy, protocol := networkIPPolicy(opts)
+	if policy != "fixedPolicy" || protocol != "IPv4" {
+		t.Errorf("got policy=%q protocol=%q, want fixedPolicy/IPv4", policy, protocol)
+	}
+}
+
+func TestDiskProvisioningBacking(t *testing.T) {
+	tests := []struct {
+		in        string
+		thin      bool
+		eager     bool
+		wantError bool
+	}{
+		{in: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin), thin: true},
+		{in: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThick)},
+		{in: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeFlat)},
+		{in: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick), eager: true},
+		{in: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeMonolithicSparse), wantError: true},
+		{in: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeSparse), wantError: true},
+	}
+
+	for _, test := range tests {
+		thin, eager, err := diskProvisioningBacking(test.in)
+		if test.wantError {
+			if err == nil {
+				t.Errorf("%s: expected an error, got nil", test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.in, err)
+			continue
+		}
+		if thin != test.thin || eager != test.eager {
+			t.Errorf("%s: got thin=%v eager=%v, want thin=%v eager=%v", test.in, thin, eager, test.thin, test.eager)
+		}
+	}
+}
+
+func diskDeviceChange(key int32) types.BaseVirtualDeviceConfigSpec {
+	return &types.VirtualDeviceConfigSpec{
+		Operation: types.VirtualDeviceConfigSpecOperationAdd,
+		Device: &types.VirtualDisk{
+			VirtualDevice: types.VirtualDevice{
+				Key: key,
+				Backing: &types.VirtualDiskFlatVer2BackingInfo{
+					ThinProvisioned: types.NewBool(false),
+					EagerlyScrub:    types.NewBool(false),
+				},
+			},
+		},
+	}
+}
+
+func TestApplyDiskProvisioning(t *testing.T) {
+	vmSpec := &types.VirtualMachineImportSpec{
+		ConfigSpec: types.VirtualMachineConfigSpec{
+			DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+				diskDeviceChange(2000),
+				diskDeviceChange(2001),
+			},
+		},
+	}
+
+	ids := []string{"vmdisk1", "vmdisk2"}
+	mapping := []Disk{
+		{ID: "vmdisk1", DiskProvisioning: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin)},
+		{ID: "vmdisk2", DiskProvisioning: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick)},
+	}
+
+	if err := applyDiskProvisioning(vmSpec, ids, mapping); err != nil {
+		t.Fatal(err)
+	}
+
+	disks := diskDeviceChanges(vmSpec)
+	b0 := disks[0].Device.(*types.VirtualDisk).Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	if !*b0.ThinProvisioned || *b0.EagerlyScrub {
+		t.Errorf("disk 0: got thin=%v eager=%v, want thin=true eager=false", *b0.ThinProvisioned, *b0.EagerlyScrub)
+	}
+
+	b1 := disks[1].Device.(*types.VirtualDisk).Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	if *b1.ThinProvisioned || !*b1.EagerlyScrub {
+		t.Errorf("disk 1: got thin=%v eager=%v, want thin=false eager=true", *b1.ThinProvisioned, *b1.EagerlyScrub)
+	}
+}
+
+func TestApplyDiskProvisioningOutOfOrderMapping(t *testing.T) {
+	vmSpec := &types.VirtualMachineImportSpec{
+		ConfigSpec: types.VirtualMachineConfigSpec{
+			DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+				diskDeviceChange(2000),
+				diskDeviceChange(2001),
+			},
+		},
+	}
+
+	ids := []string{"vmdisk1", "vmdisk2"}
+	// mapping is reverse-ordered relative to ids/disks - id lookup
+	// must still apply each override to the correct disk.
+	mapping := []Disk{
+		{ID: "vmdisk2", DiskProvisioning: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick)},
+		{ID: "vmdisk1", DiskProvisioning: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin)},
+	}
+
+	if err := applyDiskProvisioning(vmSpec, ids, mapping); err != nil {
+		t.Fatal(err)
+	}
+
+	disks := diskDeviceChanges(vmSpec)
+	b0 := disks[0].Device.(*types.VirtualDisk).Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	if !*b0.ThinProvisioned || *b0.EagerlyScrub {
+		t.Errorf("disk 0 (vmdisk1): got thin=%v eager=%v, want thin=true eager=false", *b0.ThinProvisioned, *b0.EagerlyScrub)
+	}
+
+	b1 := disks[1].Device.(*types.VirtualDisk).Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	if *b1.ThinProvisioned || !*b1.EagerlyScrub {
+		t.Errorf("disk 1 (vmdisk2): got thin=%v eager=%v, want thin=false eager=true", *b1.ThinProvisioned, *b1.EagerlyScrub)
+	}
+}
+
+func TestApplyDiskProvisioningMismatchedCountErrors(t *testing.T) {
+	vmSpec := &types.VirtualMachineImportSpec{
+		ConfigSpec: types.VirtualMachineConfigSpec{
+			DeviceChange: []types.BaseVirtualDeviceConfigSpec{diskDeviceChange(2000)},
+		},
+	}
+
+	ids := []string{"vmdisk1", "vmdisk2"}
+	mapping := []Disk{
+		{ID: "vmdisk1", DiskProvisioning: string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin)},
+	}
+
+	if err := applyDiskProvisioning(vmSpec, ids, mapping); err == nil {
+		t.Fatal("expected an error when diskIDs and device changes disagree in count, got nil")
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vim25/xml"
+)
+
+// resolveStorageProfile looks up the SPBM storage policy named name
+// and returns the VirtualMachineDefinedProfileSpec referencing it.
+//
+// VirtualMachineDefinedProfileSpec is a vim25/types type (it's what
+// ConfigSpec.VmProfile, a []BaseVirtualMachineProfileSpec, actually
+// accepts) - pbm is used only for the name->ProfileId lookup.
+func resolveStorageProfile(ctx context.Context, client *vim25.Client, name string) (*types.VirtualMachineDefinedProfileSpec, error) {
+	c, err := pbm.NewClient(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("importer: connecting to pbm: %s", err)
+	}
+
+	id, err := c.ProfileIDByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("importer: resolving storage profile %q: %s", name, err)
+	}
+
+	return &types.VirtualMachineDefinedProfileSpec{ProfileId: id}, nil
+}
+
+// decodeConfigSpec decodes opts.ConfigSpec (base64, falling back to
+// raw XML) into a VirtualMachineConfigSpec.
+//
+// This must use vim25/xml, not the stdlib encoding/xml: DeviceChange
+// and the other polymorphic interface fields on
+// VirtualMachineConfigSpec are only populated by vim25/xml's
+// xsi:type-aware decoder. The stdlib decoder silently leaves them
+// nil, which would make -config unable to add the device changes
+// (extra NICs, pvSCSI controllers, a vTPM) it exists for.
+func decodeConfigSpec(s string) (*types.VirtualMachineConfigSpec, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		raw = []byte(s)
+	}
+
+	var cs types.VirtualMachineConfigSpec
+	if err := xml.Unmarshal(raw, &cs); err != nil {
+		return nil, fmt.Errorf("importer: invalid ConfigSpec: %s", err)
+	}
+
+	return &cs, nil
+}
+
+// mergeConfigSpec overlays overlay onto base: NumCPUs, MemoryMB and
+// Firmware replace when set, DeviceChange and ExtraConfig entries
+// append; overlay's other fields are ignored. This is what
+// lets -config add hardware (extra NICs, pvSCSI controllers, a vTPM)
+// on top of the OVF-derived spec rather than replacing it outright.
+func mergeConfigSpec(base *types.VirtualMachineConfigSpec, overlay *types.VirtualMachineConfigSpec) {
+	if overlay.NumCPUs != 0 {
+		base.NumCPUs = overlay.NumCPUs
+	}
+	if overlay.MemoryMB != 0 {
+		base.MemoryMB = overlay.MemoryMB
+	}
+	if overlay.Firmware != "" {
+		base.Firmware = overlay.Firmware
+	}
+	base.DeviceChange = append(base.DeviceChange, overlay.DeviceChange...)
+	base.ExtraConfig = append(base.ExtraConfig, overlay.ExtraConfig...)
+}
+
+// applyOverlay resolves opts.StorageProfile and opts.ConfigSpec and
+// merges them into spec's VM config, in place. It is a no-op for vApp
+// (multi-VM) imports, which have no single ConfigSpec to overlay onto.
+func applyOverlay(ctx context.Context, client *vim25.Client, spec *types.OvfCreateImportSpecResult, opts *Options) error {
+	vmSpec, ok := spec.ImportSpec.(*types.VirtualMachineImportSpec)
+	if !ok {
+		return nil
+	}
+
+	if opts.StorageProfile != "" {
+		profile, err := resolveStorageProfile(ctx, client, opts.StorageProfile)
+		if err != nil {
+			return err
+		}
+		vmSpec.ConfigSpec.VmProfile = append(vmSpec.ConfigSpec.VmProfile, profile)
+		for _, disk := range diskDeviceChanges(vmSpec) {
+			disk.Profile = append(disk.Profile, profile)
+		}
+	}
+
+	if opts.ConfigSpec != "" {
+		overlay, err := decodeConfigSpec(opts.ConfigSpec)
+		if err != nil {
+			return err
+		}
+		mergeConfigSpec(&vmSpec.ConfigSpec, overlay)
+	}
+
+	return nil
+}
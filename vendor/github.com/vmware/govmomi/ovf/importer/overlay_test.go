@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const testConfigSpecXML = `<obj xmlns:vim25="urn:vim25"><numCPUs>4</numCPUs><memoryMB>4096</memoryMB></obj>`
+
+func TestDecodeConfigSpecBase64(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(testConfigSpecXML))
+
+	cs, err := decodeConfigSpec(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.NumCPUs != 4 || cs.MemoryMB != 4096 {
+		t.Errorf("got NumCPUs=%d MemoryMB=%d, want 4/4096", cs.NumCPUs, cs.MemoryMB)
+	}
+}
+
+func TestDecodeConfigSpecRawXMLFallback(t *testing.T) {
+	cs, err := decodeConfigSpec(testConfigSpecXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.NumCPUs != 4 {
+		t.Errorf("got NumCPUs=%d, want 4", cs.NumCPUs)
+	}
+}
+
+func TestMergeConfigSpec(t *testing.T) {
+	base := &types.VirtualMachineConfigSpec{
+		NumCPUs:      2,
+		ExtraConfig:  []types.BaseOptionValue{&types.OptionValue{Key: "base", Value: "1"}},
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{diskDeviceChange(2000)},
+	}
+
+	overlay := &types.VirtualMachineConfigSpec{
+		MemoryMB:     4096,
+		ExtraConfig:  []types.BaseOptionValue{&types.OptionValue{Key: "overlay", Value: "2"}},
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{diskDeviceChange(2001)},
+	}
+
+	mergeConfigSpec(base, overlay)
+
+	if base.NumCPUs != 2 {
+		t.Errorf("got NumCPUs=%d, want unchanged 2 (overlay didn't set it)", base.NumCPUs)
+	}
+	if base.MemoryMB != 4096 {
+		t.Errorf("got MemoryMB=%d, want 4096 from overlay", base.MemoryMB)
+	}
+	if len(base.ExtraConfig) != 2 {
+		t.Errorf("got %d ExtraConfig entries, want 2 (appended, not replaced)", len(base.ExtraConfig))
+	}
+	if len(base.DeviceChange) != 2 {
+		t.Errorf("got %d DeviceChange entries, want 2 (appended, not replaced)", len(base.DeviceChange))
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"encoding/xml"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ovfEnvironment and its children model enough of the OVF environment
+// document (DSP0243 section 11) to round-trip the property values a VM
+// was imported with. This is only ever marshaled (never decoded), so
+// it uses the stdlib encoding/xml rather than vim25/xml - there are no
+// polymorphic xsi:type fields here, unlike the ConfigSpec overlay
+// decoded in overlay.go.
+type ovfEnvironment struct {
+	XMLName xml.Name `xml:"Environment"`
+	Xmlns   string   `xml:"xmlns,attr"`
+
+	Property *ovfEnvPropertySection `xml:"PropertySection,omitempty"`
+}
+
+type ovfEnvPropertySection struct {
+	Properties []ovfEnvProperty `xml:"Property"`
+}
+
+type ovfEnvProperty struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// buildOvfEnv renders the OVF environment document for a VM configured
+// with properties, for guests that read it from guestinfo.ovfEnv
+// instead of having it served over the vApp-only OVF environment
+// transport (the ISO/vApp option mechanism a standalone, non-vApp VM
+// import doesn't have).
+func buildOvfEnv(properties []Property) ([]byte, error) {
+	env := ovfEnvironment{
+		Xmlns: "http://schemas.dmtf.org/ovf/environment/1",
+	}
+
+	if len(properties) > 0 {
+		section := &ovfEnvPropertySection{}
+		for _, p := range properties {
+			section.Properties = append(section.Properties, ovfEnvProperty{Key: p.Key, Value: p.Value})
+		}
+		env.Property = section
+	}
+
+	out, err := xml.MarshalIndent(&env, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+// injectOvfEnv sets guestinfo.ovfEnv on vmSpec's ConfigSpec to the OVF
+// environment document built from properties, so the guest can read
+// its assigned property values the same way it would inside a vApp.
+func injectOvfEnv(vmSpec *types.VirtualMachineImportSpec, properties []Property) error {
+	data, err := buildOvfEnv(properties)
+	if err != nil {
+		return err
+	}
+
+	vmSpec.ConfigSpec.ExtraConfig = append(vmSpec.ConfigSpec.ExtraConfig, &types.OptionValue{
+		Key:   "guestinfo.ovfEnv",
+		Value: string(data),
+	})
+
+	return nil
+}
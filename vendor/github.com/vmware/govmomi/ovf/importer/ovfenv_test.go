@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestBuildOvfEnv(t *testing.T) {
+	properties := []Property{
+		{KeyValue: KeyValue{Key: "guestinfo.hostname", Value: "appliance"}},
+	}
+
+	data, err := buildOvfEnv(properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := string(data)
+	if !strings.Contains(s, `key="guestinfo.hostname"`) || !strings.Contains(s, `value="appliance"`) {
+		t.Errorf("buildOvfEnv output missing property: %s", s)
+	}
+	if !strings.HasPrefix(s, `<?xml`) {
+		t.Errorf("buildOvfEnv output missing XML header: %s", s)
+	}
+}
+
+func TestBuildOvfEnvNoProperties(t *testing.T) {
+	data, err := buildOvfEnv(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "PropertySection") {
+		t.Errorf("expected no PropertySection with no properties, got: %s", data)
+	}
+}
+
+func TestInjectOvfEnv(t *testing.T) {
+	vmSpec := &types.VirtualMachineImportSpec{}
+	properties := []Property{{KeyValue: KeyValue{Key: "guestinfo.hostname", Value: "appliance"}}}
+
+	if err := injectOvfEnv(vmSpec, properties); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vmSpec.ConfigSpec.ExtraConfig) != 1 {
+		t.Fatalf("got %d ExtraConfig entries, want 1", len(vmSpec.ConfigSpec.ExtraConfig))
+	}
+
+	opt, ok := vmSpec.ConfigSpec.ExtraConfig[0].(*types.OptionValue)
+	if !ok {
+		t.Fatalf("ExtraConfig[0] is a %T, want *types.OptionValue", vmSpec.ConfigSpec.ExtraConfig[0])
+	}
+	if opt.Key != "guestinfo.ovfEnv" {
+		t.Errorf("got key %q, want guestinfo.ovfEnv", opt.Key)
+	}
+	if !strings.Contains(opt.Value.(string), "guestinfo.hostname") {
+		t.Errorf("ovfEnv value missing property: %v", opt.Value)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Event is a single structured progress update emitted by Import: an
+// OvfFileItem upload chunk, a lease state transition, or a
+// post-import task (mark-as-template, power-on, wait-for-IP).
+type Event struct {
+	Phase   string  `json:"phase"`
+	File    string  `json:"file,omitempty"`
+	Bytes   int64   `json:"bytes,omitempty"`
+	Total   int64   `json:"total,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	Message string  `json:"message,omitempty"`
+}
+
+// ProgressSink receives Events as Import runs. A nil ProgressSink
+// passed to Import means "don't report progress", matching the zero
+// value of the interface.
+type ProgressSink interface {
+	Report(Event)
+}
+
+// ProgressFunc adapts a plain function to a ProgressSink.
+type ProgressFunc func(Event)
+
+func (f ProgressFunc) Report(e Event) { f(e) }
+
+func report(sink ProgressSink, e Event) {
+	if sink != nil {
+		sink.Report(e)
+	}
+}
+
+// NewJSONProgressSink returns a ProgressSink that writes each Event as
+// a line of JSON to w, for CI systems and embedding provisioners that
+// want machine-readable progress.
+func NewJSONProgressSink(w io.Writer) ProgressSink {
+	return ProgressFunc(func(e Event) {
+		_ = json.NewEncoder(w).Encode(e)
+	})
+}
+
+// NewTextProgressSink returns a ProgressSink that writes each Event as
+// a human-readable line to w - roughly what the import path wrote to
+// stderr before Events existed.
+func NewTextProgressSink(w io.Writer) ProgressSink {
+	return ProgressFunc(func(e Event) {
+		switch {
+		case e.File != "" && e.Total > 0:
+			fmt.Fprintf(w, "[%s] %s: %d/%d bytes (%.0f%%)\n", e.Phase, e.File, e.Bytes, e.Total, e.Percent)
+		case e.Message != "":
+			fmt.Fprintf(w, "[%s] %s\n", e.Phase, e.Message)
+		default:
+			fmt.Fprintf(w, "[%s]\n", e.Phase)
+		}
+	})
+}
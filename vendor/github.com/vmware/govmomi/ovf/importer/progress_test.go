@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2015-2023 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewJSONProgressSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONProgressSink(&buf)
+
+	sink.Report(Event{Phase: "upload", File: "disk-0.vmdk", Bytes: 50, Total: 100, Percent: 50})
+
+	var got Event
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("decoding sink output: %s (output was %q)", err, buf.String())
+	}
+	if got.Phase != "upload" || got.File != "disk-0.vmdk" || got.Bytes != 50 || got.Total != 100 {
+		t.Errorf("got %+v, want Phase=upload File=disk-0.vmdk Bytes=50 Total=100", got)
+	}
+}
+
+func TestNewTextProgressSink(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Event
+		want string
+	}{
+		{
+			name: "upload",
+			e:    Event{Phase: "upload", File: "disk-0.vmdk", Bytes: 50, Total: 100, Percent: 50},
+			want: "[upload] disk-0.vmdk: 50/100 bytes (50%)\n",
+		},
+		{
+			name: "message",
+			e:    Event{Phase: "lease", Message: "lease ready"},
+			want: "[lease] lease ready\n",
+		},
+		{
+			name: "phase-only",
+			e:    Event{Phase: "power-on"},
+			want: "[power-on]\n",
+		},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		NewTextProgressSink(&buf).Report(test.e)
+		if buf.String() != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, buf.String(), test.want)
+		}
+	}
+}
+
+func TestReportNilSink(t *testing.T) {
+	// report must tolerate a nil ProgressSink (Import's default), not panic.
+	report(nil, Event{Phase: "upload"})
+}
+
+func TestProgressReader(t *testing.T) {
+	data := strings.Repeat("x", 100)
+	var events []Event
+
+	r := &progressReader{
+		Reader: strings.NewReader(data),
+		sink:   ProgressFunc(func(e Event) { events = append(events, e) }),
+		file:   "disk-0.vmdk",
+		total:  int64(len(data)),
+	}
+
+	buf := make([]byte, 25)
+	var reads int
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			reads++
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if reads != 4 {
+		t.Fatalf("got %d non-empty reads, want 4 (100 bytes in 25-byte chunks)", reads)
+	}
+	if len(events) == 0 {
+		t.Fatal("expected at least one progress Event to be reported")
+	}
+
+	last := events[len(events)-1]
+	if last.Bytes != 100 || last.Total != 100 || last.Percent != 100 {
+		t.Errorf("got final event %+v, want Bytes=100 Total=100 Percent=100", last)
+	}
+	if last.File != "disk-0.vmdk" {
+		t.Errorf("got File=%q, want disk-0.vmdk", last.File)
+	}
+}